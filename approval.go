@@ -0,0 +1,109 @@
+package codex
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ApprovalDecision は ApprovalRequest に対する回答です。
+type ApprovalDecision int
+
+const (
+	// Deny はコマンド/パッチの実行を拒否します。
+	Deny ApprovalDecision = iota
+	// Approve は今回のコマンド/パッチの実行のみを許可します。
+	Approve
+	// ApproveForSession は今回以降、セッション中は同様の確認を省略して許可します。
+	ApproveForSession
+)
+
+// ApprovalRequest は `untrusted` または `on-failure` の承認ポリシー使用時に
+// codex がシェルコマンドやファイルパッチの実行前に送ってくる確認要求です。
+type ApprovalRequest struct {
+	Command []string          // 実行しようとしているシェルコマンド（パッチの場合は空）
+	Patch   map[string]string // 適用しようとしているファイルパッチ（コマンドの場合は空）
+	Cwd     string            // 実行時のカレントディレクトリ
+	Reason  string            // codex が承認を求める理由
+}
+
+// ApprovalHandler は ApprovalRequest を受け取り、ApprovalDecision を返す関数です。
+type ApprovalHandler func(ctx context.Context, req ApprovalRequest) ApprovalDecision
+
+// WithApprovalHandler は `untrusted` や `on-failure` の承認ポリシー使用時に、
+// codex からのシェルコマンド/ファイルパッチの承認要求に応答するハンドラを設定する
+// オプションを返します。
+func WithApprovalHandler(handler ApprovalHandler) InvokeOption {
+	return func(o *invokeOptions) error {
+		o.ApprovalHandler = handler
+		return nil
+	}
+}
+
+// AutoApprove は allowlist に含まれるコマンドを自動的に承認する ApprovalHandler を返します。
+// allowlist の各要素は有効な正規表現としてまず評価され、コンパイルに失敗した場合は
+// 単純な前方一致（プレフィックス）として扱われます。ファイルパッチの要求は常に拒否されます。
+func AutoApprove(allowlist []string) ApprovalHandler {
+	return func(_ context.Context, req ApprovalRequest) ApprovalDecision {
+		if len(req.Command) == 0 {
+			return Deny
+		}
+		command := strings.Join(req.Command, " ")
+		for _, pattern := range allowlist {
+			if re, err := regexp.Compile(pattern); err == nil {
+				if re.MatchString(command) {
+					return Approve
+				}
+				continue
+			}
+			if strings.HasPrefix(command, pattern) {
+				return Approve
+			}
+		}
+		return Deny
+	}
+}
+
+// elicitationHandler は opts.ApprovalHandler を MCP の elicitation ハンドラに変換します。
+// ApprovalHandler が設定されていない場合は nil を返し、codex からの elicitation
+// リクエストは MCP SDK のデフォルト動作（拒否）に委ねられます。
+func (opts invokeOptions) elicitationHandler() func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	if opts.ApprovalHandler == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, elicit *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+		var payload struct {
+			Command []string          `json:"command"`
+			Patch   map[string]string `json:"patch"`
+			Cwd     string            `json:"cwd"`
+			Reason  string            `json:"reason"`
+		}
+		if err := json.Unmarshal([]byte(elicit.Params.Message), &payload); err != nil {
+			// 承認要求の内容を解釈できない以上、空の ApprovalRequest を
+			// ApprovalHandler に渡して判断を委ねるのは危険なので、fail closed で拒否する。
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		}
+
+		req := ApprovalRequest{
+			Command: payload.Command,
+			Patch:   payload.Patch,
+			Cwd:     payload.Cwd,
+			Reason:  payload.Reason,
+		}
+
+		decision := opts.ApprovalHandler(ctx, req)
+
+		switch decision {
+		case Approve:
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"decision": "approved"}}, nil
+		case ApproveForSession:
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"decision": "approved_for_session"}}, nil
+		default:
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		}
+	}
+}
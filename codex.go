@@ -30,6 +30,15 @@ func WithLogger(w io.Writer, level string) CodexOption {
 	}
 }
 
+// WithDefaultMCPServer は、この Codex から実行するすべての呼び出しに対して
+// 追加の MCP サーバーを登録するオプションを返します。個別の呼び出しで
+// WithMCPServer により同名のサーバーが指定された場合はそちらが優先されます。
+func WithDefaultMCPServer(name string, spec MCPServerSpec) CodexOption {
+	return func(codex *Codex) {
+		codex.SetMCPServer(name, spec)
+	}
+}
+
 // Codex は codex コマンドをラップするクライアント構造体です。
 // 内部で実行コマンドのパスやログ設定、認証用のロックを保持します。
 type Codex struct {
@@ -38,6 +47,8 @@ type Codex struct {
 	executablePath string    // 実行コマンドのパス（デフォルト：codex）
 	logWriter      io.Writer // ログの出力先（デフォルト：nil）
 	logLevel       string    // ログレベル（デフォルト：info、有効な値：error, warn, info, debug, trace, off）
+
+	mcpServers map[string]MCPServerSpec // デフォルトで接続する MCP サーバー
 }
 
 // New は Codex のインスタンスを作成します。
@@ -68,6 +79,39 @@ func (codex *Codex) SetLogger(w io.Writer, level string) {
 	codex.logLevel = level
 }
 
+// SetMCPServer は、この Codex から実行するすべての呼び出しに対して
+// 追加の MCP サーバーを登録します。`WithDefaultMCPServer` と同様の振る舞いを持ち、
+// インスタンス生成後に設定を変更できます。
+func (codex *Codex) SetMCPServer(name string, spec MCPServerSpec) {
+	if codex.mcpServers == nil {
+		codex.mcpServers = map[string]MCPServerSpec{}
+	}
+	codex.mcpServers[name] = spec
+}
+
+// applyDefaultMCPServers は codex.mcpServers のうち、opts 側でまだ
+// 指定されていないものだけを opts.Config にマージします。
+func (codex *Codex) applyDefaultMCPServers(opts *invokeOptions) {
+	if len(codex.mcpServers) == 0 {
+		return
+	}
+
+	if opts.Config == nil {
+		opts.Config = map[string]any{}
+	}
+	mcpServers, _ := opts.Config["mcp_servers"].(map[string]any)
+	if mcpServers == nil {
+		mcpServers = map[string]any{}
+	}
+	for name, spec := range codex.mcpServers {
+		if _, exists := mcpServers[name]; exists {
+			continue
+		}
+		mcpServers[name] = spec.toConfig()
+	}
+	opts.Config["mcp_servers"] = mcpServers
+}
+
 func (codex *Codex) command(ctx context.Context, arg ...string) (*exec.Cmd, error) {
 	cmd := exec.CommandContext(ctx, codex.executablePath, arg...)
 	if codex.logWriter != nil {
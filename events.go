@@ -0,0 +1,183 @@
+package codex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// EventType は Invoke のストリーミングで送出されるイベントの種別です。
+type EventType string
+
+const (
+	EventAgentMessageDelta EventType = "agent_message_delta"
+	EventExecCommand       EventType = "exec_command"
+	EventPatchApply        EventType = "patch_apply"
+	EventPlanUpdate        EventType = "plan_update"
+	EventTokenCount        EventType = "token_count"
+	EventError             EventType = "error"
+)
+
+// Event は codex の実行中に届く MCP 通知を表すイベントです。
+// Type の値に応じて、対応するフィールドのみが設定されます。
+type Event struct {
+	Type EventType
+
+	AgentMessageDelta *AgentMessageDeltaEvent
+	ExecCommand       *ExecCommandEvent
+	PatchApply        *PatchApplyEvent
+	PlanUpdate        *PlanUpdateEvent
+	TokenCount        *TokenCountEvent
+	Err               error
+}
+
+// AgentMessageDeltaEvent はエージェントの応答が少しずつ生成される際の
+// 差分テキストを表します。
+type AgentMessageDeltaEvent struct {
+	Delta string `json:"delta"`
+}
+
+// ExecCommandEvent はエージェントが実行しようとしている、
+// あるいは実行し終えたシェルコマンドを表します。
+type ExecCommandEvent struct {
+	CallID   string   `json:"call_id"`
+	Command  []string `json:"command"`
+	Cwd      string   `json:"cwd"`
+	Output   string   `json:"output,omitempty"`    // コマンド完了後にのみ設定される
+	ExitCode *int     `json:"exit_code,omitempty"` // コマンド完了後にのみ設定される
+}
+
+// PatchApplyEvent はエージェントが適用しようとしている、
+// あるいは適用し終えたファイルパッチを表します。
+type PatchApplyEvent struct {
+	CallID  string            `json:"call_id"`
+	Changes map[string]string `json:"changes"`           // ファイルパスごとの差分
+	Success *bool             `json:"success,omitempty"` // 適用完了後にのみ設定される
+}
+
+// PlanUpdateEvent はプランツールによって更新されたタスク計画を表します。
+type PlanUpdateEvent struct {
+	Explanation string     `json:"explanation,omitempty"`
+	Plan        []PlanStep `json:"plan"`
+}
+
+// PlanStep はプラン内の1つのタスクを表します。
+type PlanStep struct {
+	Step   string `json:"step"`
+	Status string `json:"status"` // pending, in_progress, completed
+}
+
+// TokenCountEvent は現在までのトークン使用量を表します。
+// Model には、その使用量を計上したターンで実際に使われたモデル名が
+// codex から報告された場合に設定されます。
+type TokenCountEvent struct {
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	CachedTokens int    `json:"cached_tokens"`
+	Model        string `json:"model,omitempty"`
+}
+
+var progressTokenCounter int64
+
+// newProgressToken は CallTool のリクエストごとに一意な progress token を発行します。
+// 文字列として発行するのは、progressToken がプロセス境界を越えて JSON で
+// 往復する際に、数値だと `any` へのデコード時に float64 になってしまい
+// 送信側の int64/string との比較が壊れるのを避けるためです。
+func newProgressToken() string {
+	return strconv.FormatInt(atomic.AddInt64(&progressTokenCounter, 1), 10)
+}
+
+// progressTokenKey は MCP の progress token（`any` としてデコードされる）を
+// subscriber 検索用の比較可能なマップキーに正規化します。
+func progressTokenKey(token any) string {
+	return fmt.Sprint(token)
+}
+
+// eventSender は ctx が完了しても Event チャンネルへの送信でブロックし続けない
+// ようにするヘルパーです。InvokeStream と Session.SendStream の両方で、
+// 呼び出し元がチャンネルの受信をやめて離脱し得る箇所に共通して使われます。
+type eventSender struct {
+	events chan<- Event
+	done   <-chan struct{}
+
+	// sawAgentMessageDelta が設定されている場合、EventAgentMessageDelta を
+	// 送信するたびに true が記録されます。呼び出し側はこれを見て、
+	// codex からの最終応答テキストを agent_message_delta 通知の重複として
+	// 送り直さないようにします。
+	sawAgentMessageDelta *atomic.Bool
+}
+
+// send は ctx が完了していない限り event をチャンネルに送信します。
+// 呼び出し元が受信をやめて done がクローズされた場合は、送信をあきらめて即座に戻ります。
+func (s eventSender) send(event Event) {
+	if s.sawAgentMessageDelta != nil && event.Type == EventAgentMessageDelta {
+		s.sawAgentMessageDelta.Store(true)
+	}
+	select {
+	case s.events <- event:
+	case <-s.done:
+	}
+}
+
+// decodeEvent は codex から届いた progress 通知のメッセージ部分を Event に
+// デコードします。未知の種別の通知は ok=false を返し、呼び出し側で無視されます。
+func decodeEvent(raw []byte) (Event, bool) {
+	var envelope struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return Event{}, false
+	}
+
+	switch envelope.Type {
+	case "agent_message_delta":
+		var data AgentMessageDeltaEvent
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return Event{Type: EventError, Err: err}, true
+		}
+		return Event{Type: EventAgentMessageDelta, AgentMessageDelta: &data}, true
+
+	case "exec_command_begin", "exec_command_end":
+		var data ExecCommandEvent
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return Event{Type: EventError, Err: err}, true
+		}
+		return Event{Type: EventExecCommand, ExecCommand: &data}, true
+
+	case "patch_apply_begin", "patch_apply_end":
+		var data PatchApplyEvent
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return Event{Type: EventError, Err: err}, true
+		}
+		return Event{Type: EventPatchApply, PatchApply: &data}, true
+
+	case "plan_update":
+		var data PlanUpdateEvent
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return Event{Type: EventError, Err: err}, true
+		}
+		return Event{Type: EventPlanUpdate, PlanUpdate: &data}, true
+
+	case "token_count":
+		var data TokenCountEvent
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return Event{Type: EventError, Err: err}, true
+		}
+		return Event{Type: EventTokenCount, TokenCount: &data}, true
+
+	case "error":
+		var data struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return Event{Type: EventError, Err: err}, true
+		}
+		return Event{Type: EventError, Err: errors.New(data.Message)}, true
+
+	default:
+		return Event{}, false
+	}
+}
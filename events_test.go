@@ -0,0 +1,90 @@
+package codex
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEventSenderSendDeliversEvent(t *testing.T) {
+	events := make(chan Event, 1)
+	sender := eventSender{events: events, done: make(chan struct{})}
+
+	sender.send(Event{Type: EventAgentMessageDelta, AgentMessageDelta: &AgentMessageDeltaEvent{Delta: "hello"}})
+
+	select {
+	case got := <-events:
+		if got.Type != EventAgentMessageDelta || got.AgentMessageDelta.Delta != "hello" {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+	default:
+		t.Fatal("expected event to be delivered")
+	}
+}
+
+func TestEventSenderMarksSawAgentMessageDelta(t *testing.T) {
+	events := make(chan Event, 1)
+	sawDelta := &atomic.Bool{}
+	sender := eventSender{events: events, done: make(chan struct{}), sawAgentMessageDelta: sawDelta}
+
+	sender.send(Event{Type: EventAgentMessageDelta, AgentMessageDelta: &AgentMessageDeltaEvent{Delta: "hi"}})
+
+	if !sawDelta.Load() {
+		t.Fatal("expected sawAgentMessageDelta to be set after sending an agent_message_delta event")
+	}
+}
+
+func TestEventSenderDoesNotBlockWhenConsumerAbandonsChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event) // 受信側が一切 receive しない、かつバッファなし
+	sender := eventSender{events: events, done: ctx.Done()}
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		sender.send(Event{Type: EventAgentMessageDelta, AgentMessageDelta: &AgentMessageDeltaEvent{Delta: "abandoned"}})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("send blocked even though ctx was already done")
+	}
+}
+
+func TestProgressTokenKeyNormalizesAcrossTypes(t *testing.T) {
+	// JSON 経由で往復すると数値は float64 にデコードされるため、送信側の
+	// string/int64 と一致しなくなる問題を防げているかを確認する。
+	tests := []struct {
+		a, b any
+	}{
+		{a: "1", b: float64(1)},
+		{a: int64(2), b: float64(2)},
+	}
+
+	for _, tt := range tests {
+		if progressTokenKey(tt.a) != progressTokenKey(tt.b) {
+			t.Fatalf("expected progressTokenKey(%v) == progressTokenKey(%v), got %q != %q", tt.a, tt.b, progressTokenKey(tt.a), progressTokenKey(tt.b))
+		}
+	}
+}
+
+func TestDecodeEventAgentMessageDelta(t *testing.T) {
+	event, ok := decodeEvent([]byte(`{"type":"agent_message_delta","data":{"delta":"hi"}}`))
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if event.Type != EventAgentMessageDelta || event.AgentMessageDelta.Delta != "hi" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
+
+func TestDecodeEventUnknownTypeIsIgnored(t *testing.T) {
+	_, ok := decodeEvent([]byte(`{"type":"something_unknown","data":{}}`))
+	if ok {
+		t.Fatal("expected ok=false for unknown event type")
+	}
+}
@@ -3,17 +3,20 @@ package codex
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 type invokeOptions struct {
+	ApprovalHandler  ApprovalHandler
 	ApprovalPolicy   *string // untrusted, on-failure, never
 	BaseInstructions *string
 	Config           map[string]any
 	Cwd              *string // 実行時のカレントディレクトリ
 	IncludePlanTool  *bool
 	Model            *string // モデル
+	Pricing          map[string]Pricing
 	Profile          *string
 	Sandbox          *string // read-only, workspace-write, danger-full-access
 }
@@ -99,33 +102,8 @@ func WithSandbox(sandbox string) InvokeOption {
 	}
 }
 
-// Invoke は Codex を実行して結果を返します。
-// 指定可能なオプションの詳細は以下を参照してください。
-// https://github.com/openai/codex/blob/main/docs/advanced.md#codex-mcp-server-quickstart
-func (codex *Codex) Invoke(ctx context.Context, prompt string, options ...InvokeOption) (string, error) {
-	opts := invokeOptions{}
-	for _, opt := range options {
-		if err := opt(&opts); err != nil {
-			return "", err
-		}
-	}
-
-	cmd, err := codex.command(ctx, "mcp")
-	if err != nil {
-		return "", err
-	}
-
-	if opts.Cwd != nil {
-		cmd.Dir = *opts.Cwd
-	}
-
-	client := mcp.NewClient(&mcp.Implementation{}, nil)
-	session, err := client.Connect(ctx, &mcp.CommandTransport{Command: cmd}, nil)
-	if err != nil {
-		return "", err
-	}
-	defer session.Close()
-
+// buildArguments は invokeOptions から "codex" ツール呼び出しに渡す引数を組み立てます。
+func (opts *invokeOptions) buildArguments(prompt string) map[string]any {
 	arguments := map[string]any{
 		"prompt": prompt,
 	}
@@ -158,9 +136,52 @@ func (codex *Codex) Invoke(ctx context.Context, prompt string, options ...Invoke
 		arguments["sandbox"] = *opts.Sandbox
 	}
 
+	return arguments
+}
+
+// connect は invokeOptions にもとづいて `codex mcp` を起動し、MCP セッションを確立します。
+// progressHandler が指定された場合、codex からの progress 通知を受け取ります。
+// opts に WithApprovalHandler が設定されていれば、elicitation リクエストも併せて処理します。
+func (codex *Codex) connect(ctx context.Context, opts invokeOptions, progressHandler func(context.Context, *mcp.ProgressNotificationClientRequest)) (*mcp.ClientSession, error) {
+	cmd, err := codex.command(ctx, "mcp")
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Cwd != nil {
+		cmd.Dir = *opts.Cwd
+	}
+
+	clientOptions := &mcp.ClientOptions{
+		ProgressNotificationHandler: progressHandler,
+		ElicitationHandler:          opts.elicitationHandler(),
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{}, clientOptions)
+	return client.Connect(ctx, &mcp.CommandTransport{Command: cmd}, nil)
+}
+
+// Invoke は Codex を実行して結果を返します。
+// 指定可能なオプションの詳細は以下を参照してください。
+// https://github.com/openai/codex/blob/main/docs/advanced.md#codex-mcp-server-quickstart
+func (codex *Codex) Invoke(ctx context.Context, prompt string, options ...InvokeOption) (string, error) {
+	opts := invokeOptions{}
+	for _, opt := range options {
+		if err := opt(&opts); err != nil {
+			return "", err
+		}
+	}
+	codex.applyDefaultMCPServers(&opts)
+
+	session, err := codex.connect(ctx, opts, nil)
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
 	params := &mcp.CallToolParams{
 		Name:      "codex",
-		Arguments: arguments,
+		Arguments: opts.buildArguments(prompt),
 	}
 	res, err := session.CallTool(ctx, params)
 	if err != nil {
@@ -174,3 +195,65 @@ func (codex *Codex) Invoke(ctx context.Context, prompt string, options ...Invoke
 
 	return text, nil
 }
+
+// InvokeStream は Invoke と同様に Codex を実行しますが、完了を待たずに
+// エージェントの途中経過（推論の差分、コマンド実行、パッチ適用、プラン更新、
+// トークン使用量など）を Event チャンネルとして返します。
+// チャンネルは codex の実行が完了すると自動的にクローズされます。
+func (codex *Codex) InvokeStream(ctx context.Context, prompt string, options ...InvokeOption) (<-chan Event, error) {
+	opts := invokeOptions{}
+	for _, opt := range options {
+		if err := opt(&opts); err != nil {
+			return nil, err
+		}
+	}
+	codex.applyDefaultMCPServers(&opts)
+
+	events := make(chan Event)
+	sender := eventSender{events: events, done: ctx.Done(), sawAgentMessageDelta: &atomic.Bool{}}
+
+	progressHandler := func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+		event, ok := decodeEvent([]byte(req.Params.Message))
+		if !ok {
+			return
+		}
+		sender.send(event)
+	}
+
+	session, err := codex.connect(ctx, opts, progressHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	progressToken := newProgressToken()
+
+	go func() {
+		defer close(events)
+		defer session.Close()
+
+		params := &mcp.CallToolParams{
+			Name:      "codex",
+			Arguments: opts.buildArguments(prompt),
+			Meta:      mcp.Meta{"progressToken": progressToken},
+		}
+		res, err := session.CallTool(ctx, params)
+		if err != nil {
+			sender.send(Event{Type: EventError, Err: err})
+			return
+		}
+
+		text := res.Content[0].(*mcp.TextContent).Text
+		if res.IsError {
+			sender.send(Event{Type: EventError, Err: errors.New(text)})
+			return
+		}
+
+		// codex が既に agent_message_delta 通知でテキストをストリーミング済みなら、
+		// 同じ内容を最終応答として重複送出しない。
+		if !sender.sawAgentMessageDelta.Load() {
+			sender.send(Event{Type: EventAgentMessageDelta, AgentMessageDelta: &AgentMessageDeltaEvent{Delta: text}})
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,222 @@
+package codex
+
+import (
+	"context"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ExecRecord は Invoke 中に実行されたシェルコマンド1件の記録です。
+type ExecRecord struct {
+	CallID   string
+	Command  []string
+	Cwd      string
+	Output   string
+	ExitCode *int
+}
+
+// PatchRecord は Invoke 中に適用されたファイルパッチ1件の記録です。
+type PatchRecord struct {
+	CallID  string
+	Changes map[string]string
+	Success *bool
+}
+
+// Pricing は 100万トークンあたりの概算価格（USD）です。
+type Pricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+	CachedPerMillion float64
+}
+
+// defaultPricing はモデルごとのデフォルト価格表です（100万トークンあたりの USD）。
+// あくまで概算であり、正確な値が必要な場合は WithPricing で上書きしてください。
+var defaultPricing = map[string]Pricing{
+	"gpt-5":      {InputPerMillion: 5, OutputPerMillion: 15, CachedPerMillion: 1.25},
+	"gpt-5-mini": {InputPerMillion: 0.25, OutputPerMillion: 2, CachedPerMillion: 0.025},
+	"o3":         {InputPerMillion: 2, OutputPerMillion: 8, CachedPerMillion: 0.5},
+}
+
+// WithPricing は EstimatedCostUSD の算出に使うモデルごとの価格表を上書きする
+// オプションを返します。ここに含まれないモデルについては内部のデフォルト価格表が使われます。
+func WithPricing(pricing map[string]Pricing) InvokeOption {
+	return func(o *invokeOptions) error {
+		o.Pricing = pricing
+		return nil
+	}
+}
+
+// InvokeResult は InvokeDetailed の戻り値です。
+// 最終的な応答テキストに加え、使用したモデルやトークン使用量、概算コスト、
+// 実行されたコマンド/パッチの記録、最後に通知されたプランを保持します。
+type InvokeResult struct {
+	Text  string
+	Model string
+
+	InputTokens  int
+	OutputTokens int
+	CachedTokens int
+
+	EstimatedCostUSD float64
+
+	ExecCalls  []ExecRecord
+	PatchCalls []PatchRecord
+	PlanSteps  []PlanStep
+}
+
+// InvokeDetailed は Invoke と同様に Codex を実行しますが、最終的な応答テキストのみを
+// 返す代わりに、トークン使用量や概算コスト、実行されたコマンド/パッチの記録、
+// 最後に通知されたプランを含む InvokeResult を返します。InvokeStream と同じ通知の購読を使って
+// 収集するため、予算管理や監査ログ、レート制限を呼び出し側で実装できます。
+func (codex *Codex) InvokeDetailed(ctx context.Context, prompt string, options ...InvokeOption) (*InvokeResult, error) {
+	opts := invokeOptions{}
+	for _, opt := range options {
+		if err := opt(&opts); err != nil {
+			return nil, err
+		}
+	}
+	codex.applyDefaultMCPServers(&opts)
+
+	collector := newInvokeResultCollector()
+
+	progressHandler := func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+		event, ok := decodeEvent([]byte(req.Params.Message))
+		if !ok {
+			return
+		}
+		collector.apply(event)
+	}
+
+	session, err := codex.connect(ctx, opts, progressHandler)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	params := &mcp.CallToolParams{
+		Name:      "codex",
+		Arguments: opts.buildArguments(prompt),
+		Meta:      mcp.Meta{"progressToken": newProgressToken()},
+	}
+	res, err := session.CallTool(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	text := res.Content[0].(*mcp.TextContent).Text
+	if res.IsError {
+		return nil, errors.New(text)
+	}
+
+	result := collector.result
+	result.Text = text
+
+	if result.Model == "" && opts.Model != nil {
+		// codex がどのモデルを実際に使ったか報告しなかった場合のみ、
+		// 呼び出し時に指定したモデル名で補います（実際に使われた保証はありません）。
+		result.Model = *opts.Model
+	}
+	result.EstimatedCostUSD = result.estimateCost(opts.Pricing)
+
+	return result, nil
+}
+
+// invokeResultCollector は InvokeDetailed が受け取る progress 通知から
+// InvokeResult を組み立てます。exec/patch の開始・完了通知は CallID で
+// 突き合わせて1レコードにまとめます（重複防止）。
+type invokeResultCollector struct {
+	result *InvokeResult
+
+	execIndex  map[string]int // CallID -> result.ExecCalls のインデックス
+	patchIndex map[string]int // CallID -> result.PatchCalls のインデックス
+}
+
+func newInvokeResultCollector() *invokeResultCollector {
+	return &invokeResultCollector{
+		result:     &InvokeResult{},
+		execIndex:  map[string]int{},
+		patchIndex: map[string]int{},
+	}
+}
+
+// apply は1件の Event を InvokeResult に反映します。
+func (c *invokeResultCollector) apply(event Event) {
+	result := c.result
+
+	switch event.Type {
+	case EventExecCommand:
+		data := event.ExecCommand
+		if i, exists := c.execIndex[data.CallID]; exists && data.CallID != "" {
+			record := &result.ExecCalls[i]
+			if len(data.Command) > 0 {
+				record.Command = data.Command
+			}
+			if data.Cwd != "" {
+				record.Cwd = data.Cwd
+			}
+			if data.Output != "" {
+				record.Output = data.Output
+			}
+			if data.ExitCode != nil {
+				record.ExitCode = data.ExitCode
+			}
+		} else {
+			c.execIndex[data.CallID] = len(result.ExecCalls)
+			result.ExecCalls = append(result.ExecCalls, ExecRecord{
+				CallID:   data.CallID,
+				Command:  data.Command,
+				Cwd:      data.Cwd,
+				Output:   data.Output,
+				ExitCode: data.ExitCode,
+			})
+		}
+
+	case EventPatchApply:
+		data := event.PatchApply
+		if i, exists := c.patchIndex[data.CallID]; exists && data.CallID != "" {
+			record := &result.PatchCalls[i]
+			if len(data.Changes) > 0 {
+				record.Changes = data.Changes
+			}
+			if data.Success != nil {
+				record.Success = data.Success
+			}
+		} else {
+			c.patchIndex[data.CallID] = len(result.PatchCalls)
+			result.PatchCalls = append(result.PatchCalls, PatchRecord{
+				CallID:  data.CallID,
+				Changes: data.Changes,
+				Success: data.Success,
+			})
+		}
+
+	case EventPlanUpdate:
+		result.PlanSteps = event.PlanUpdate.Plan
+
+	case EventTokenCount:
+		result.InputTokens = event.TokenCount.InputTokens
+		result.OutputTokens = event.TokenCount.OutputTokens
+		result.CachedTokens = event.TokenCount.CachedTokens
+		if event.TokenCount.Model != "" {
+			result.Model = event.TokenCount.Model
+		}
+	}
+}
+
+// estimateCost は InputTokens/OutputTokens/CachedTokens と価格表から概算コストを算出します。
+// Model に対応する価格が overrides にも defaultPricing にも見つからない場合は 0 を返します。
+func (result *InvokeResult) estimateCost(overrides map[string]Pricing) float64 {
+	pricing, ok := overrides[result.Model]
+	if !ok {
+		pricing, ok = defaultPricing[result.Model]
+	}
+	if !ok {
+		return 0
+	}
+
+	inputTokens := result.InputTokens - result.CachedTokens
+	return float64(inputTokens)/1_000_000*pricing.InputPerMillion +
+		float64(result.OutputTokens)/1_000_000*pricing.OutputPerMillion +
+		float64(result.CachedTokens)/1_000_000*pricing.CachedPerMillion
+}
@@ -0,0 +1,78 @@
+package codex
+
+import "testing"
+
+func TestInvokeResultCollectorMergesExecCallsByCallID(t *testing.T) {
+	collector := newInvokeResultCollector()
+
+	collector.apply(Event{Type: EventExecCommand, ExecCommand: &ExecCommandEvent{
+		CallID:  "call-1",
+		Command: []string{"ls", "-la"},
+		Cwd:     "/tmp",
+	}})
+	exitCode := 0
+	collector.apply(Event{Type: EventExecCommand, ExecCommand: &ExecCommandEvent{
+		CallID:   "call-1",
+		Output:   "file1\nfile2\n",
+		ExitCode: &exitCode,
+	}})
+
+	if len(collector.result.ExecCalls) != 1 {
+		t.Fatalf("expected exec_command_begin/end with the same CallID to merge into one record, got %d", len(collector.result.ExecCalls))
+	}
+	record := collector.result.ExecCalls[0]
+	if record.Cwd != "/tmp" || record.Output != "file1\nfile2\n" || record.ExitCode == nil || *record.ExitCode != 0 {
+		t.Fatalf("unexpected merged record: %+v", record)
+	}
+}
+
+func TestInvokeResultCollectorMergesPatchCallsByCallID(t *testing.T) {
+	collector := newInvokeResultCollector()
+
+	collector.apply(Event{Type: EventPatchApply, PatchApply: &PatchApplyEvent{
+		CallID:  "call-1",
+		Changes: map[string]string{"main.go": "+1 line"},
+	}})
+	success := true
+	collector.apply(Event{Type: EventPatchApply, PatchApply: &PatchApplyEvent{
+		CallID:  "call-1",
+		Success: &success,
+	}})
+
+	if len(collector.result.PatchCalls) != 1 {
+		t.Fatalf("expected patch_apply_begin/end with the same CallID to merge into one record, got %d", len(collector.result.PatchCalls))
+	}
+	record := collector.result.PatchCalls[0]
+	if record.Changes["main.go"] != "+1 line" || record.Success == nil || !*record.Success {
+		t.Fatalf("unexpected merged record: %+v", record)
+	}
+}
+
+func TestInvokeResultCollectorDistinctCallIDsProduceSeparateRecords(t *testing.T) {
+	collector := newInvokeResultCollector()
+
+	collector.apply(Event{Type: EventExecCommand, ExecCommand: &ExecCommandEvent{CallID: "call-1", Command: []string{"a"}}})
+	collector.apply(Event{Type: EventExecCommand, ExecCommand: &ExecCommandEvent{CallID: "call-2", Command: []string{"b"}}})
+
+	if len(collector.result.ExecCalls) != 2 {
+		t.Fatalf("expected 2 separate exec records, got %d", len(collector.result.ExecCalls))
+	}
+}
+
+func TestInvokeResultCollectorTokenCountAndPlanUpdate(t *testing.T) {
+	collector := newInvokeResultCollector()
+
+	collector.apply(Event{Type: EventPlanUpdate, PlanUpdate: &PlanUpdateEvent{
+		Plan: []PlanStep{{Step: "do thing", Status: "in_progress"}},
+	}})
+	collector.apply(Event{Type: EventTokenCount, TokenCount: &TokenCountEvent{
+		InputTokens: 10, OutputTokens: 20, CachedTokens: 5, Model: "gpt-5",
+	}})
+
+	if len(collector.result.PlanSteps) != 1 || collector.result.PlanSteps[0].Step != "do thing" {
+		t.Fatalf("unexpected plan steps: %+v", collector.result.PlanSteps)
+	}
+	if collector.result.InputTokens != 10 || collector.result.OutputTokens != 20 || collector.result.CachedTokens != 5 || collector.result.Model != "gpt-5" {
+		t.Fatalf("unexpected token/model bookkeeping: %+v", collector.result)
+	}
+}
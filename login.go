@@ -1,6 +1,14 @@
 package codex
 
-import "context"
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
 
 // Login は OpenAI の API Key を使用して Codex の認証を行います。
 func (codex *Codex) Login(ctx context.Context, apiKey string) error {
@@ -17,3 +25,131 @@ func (codex *Codex) Login(ctx context.Context, apiKey string) error {
 
 	return nil
 }
+
+type loginOptions struct {
+	OnAuthURL func(url string)
+}
+
+// LoginOption は LoginWithOAuth 呼び出しに渡すオプション関数の型です。
+type LoginOption func(*loginOptions)
+
+// WithOnAuthURL は、ブラウザでの認証に使う localhost コールバック URL を
+// codex が出力した際に呼び出されるコールバックを設定するオプションを返します。
+// 呼び出し側はこれを使ってブラウザを開いたり、URL をユーザーに転送したりできます。
+func WithOnAuthURL(onAuthURL func(url string)) LoginOption {
+	return func(o *loginOptions) {
+		o.OnAuthURL = onAuthURL
+	}
+}
+
+var authURLPattern = regexp.MustCompile(`https?://\S+`)
+
+// LoginWithOAuth は ChatGPT アカウントによるブラウザ認証（OAuth）で
+// Codex の認証を行います。codex が標準出力に表示する localhost への
+// コールバック URL を検出すると、WithOnAuthURL で指定したコールバックに通知します。
+func (codex *Codex) LoginWithOAuth(ctx context.Context, options ...LoginOption) error {
+	codex.authMutex.Lock()
+	defer codex.authMutex.Unlock()
+
+	opts := loginOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	cmd, err := codex.command(ctx, "login")
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if opts.OnAuthURL == nil {
+			continue
+		}
+		if url := authURLPattern.FindString(line); url != "" {
+			opts.OnAuthURL(url)
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		_ = cmd.Wait()
+		return err
+	}
+
+	return cmd.Wait()
+}
+
+// LoginStatus は Codex の現在のサインイン状態を表します。
+type LoginStatus struct {
+	SignedIn    bool
+	AccountType string // "api-key" または "chatgpt"
+	Expiry      *time.Time
+}
+
+var expiryPattern = regexp.MustCompile(`(?i)expir\w*\D*(\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}:\d{2})?)`)
+
+// LoginStatus は `codex login status` を実行し、現在のサインイン状態を返します。
+func (codex *Codex) LoginStatus(ctx context.Context) (LoginStatus, error) {
+	cmd, err := codex.command(ctx, "login", "status")
+	if err != nil {
+		return LoginStatus{}, err
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return LoginStatus{}, err
+	}
+	text := string(out)
+
+	status := LoginStatus{}
+	lower := strings.ToLower(text)
+	switch {
+	case strings.Contains(lower, "chatgpt"):
+		status.SignedIn = true
+		status.AccountType = "chatgpt"
+	case strings.Contains(lower, "api key"):
+		status.SignedIn = true
+		status.AccountType = "api-key"
+	default:
+		// "not logged in" のような既知の否定文言だけでなく、
+		// 認識できない出力についても fail closed でサインインなしとして扱う。
+		status.SignedIn = false
+	}
+
+	if m := expiryPattern.FindStringSubmatch(text); m != nil {
+		if expiry, parseErr := time.Parse(time.RFC3339, m[1]); parseErr == nil {
+			status.Expiry = &expiry
+		} else if expiry, parseErr := time.Parse("2006-01-02T15:04:05", m[1]); parseErr == nil {
+			status.Expiry = &expiry
+		} else if expiry, parseErr := time.Parse("2006-01-02", m[1]); parseErr == nil {
+			status.Expiry = &expiry
+		}
+	}
+
+	return status, nil
+}
+
+// Logout は Codex のサインイン状態を解除します。
+func (codex *Codex) Logout(ctx context.Context) error {
+	codex.authMutex.Lock()
+	defer codex.authMutex.Unlock()
+	cmd, err := codex.command(ctx, "logout")
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	return nil
+}
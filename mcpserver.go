@@ -0,0 +1,98 @@
+package codex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MCPServerSpec は codex 自身が追加で接続する MCP サーバーの設定です。
+// Command を指定すると標準入出力（stdio）経由、URL を指定すると SSE/HTTP 経由で接続します。
+type MCPServerSpec struct {
+	Command string            // stdio 接続時に起動するコマンド
+	Args    []string          // Command に渡す引数
+	Env     map[string]string // Command に渡す追加の環境変数
+	URL     string            // SSE/HTTP 接続時の接続先 URL
+}
+
+// toConfig は MCPServerSpec を `mcp_servers.<name>` 以下の config エントリに変換します。
+func (spec MCPServerSpec) toConfig() map[string]any {
+	config := map[string]any{}
+
+	if spec.Command != "" {
+		config["command"] = spec.Command
+		if len(spec.Args) > 0 {
+			config["args"] = spec.Args
+		}
+		if len(spec.Env) > 0 {
+			config["env"] = spec.Env
+		}
+	}
+
+	if spec.URL != "" {
+		config["url"] = spec.URL
+	}
+
+	return config
+}
+
+// WithMCPServer は、この呼び出しに限り codex に追加で接続させる MCP サーバーを
+// 登録するオプションを返します。指定内容は `mcp_servers.<name>.*` の config
+// エントリとして合成され、WithConfig で渡された設定にマージされます。
+// WithConfig をこのオプションより後に指定した場合は、WithConfig の設定で
+// 置き換えられ、ここで登録したサーバーは含まれません。
+// これにより、ファイルシステムや git、独自実装の MCP サーバーをツールとして
+// 組み込めます。
+func WithMCPServer(name string, spec MCPServerSpec) InvokeOption {
+	return func(o *invokeOptions) error {
+		if o.Config == nil {
+			o.Config = map[string]any{}
+		}
+		mcpServers, _ := o.Config["mcp_servers"].(map[string]any)
+		if mcpServers == nil {
+			mcpServers = map[string]any{}
+		}
+		mcpServers[name] = spec.toConfig()
+		o.Config["mcp_servers"] = mcpServers
+		return nil
+	}
+}
+
+// ServeMCP は in-process に実装した *mcp.Server を listener 上で待ち受けさせ、
+// codex から MCPServerSpec の URL として接続できるアドレスを返します。
+// ctx がキャンセルされると、呼び出し側が shutdown を呼ばなくてもサーバーを停止します。
+// 返り値の errs は Serve がリスナー障害などで終了した際にその原因を1件だけ受け取る
+// チャンネルで、サーバーが shutdown によって正常に停止した場合はクローズのみされます。
+// これにより、ホストプログラムが実装したツールを codex のエージェントに
+// 公開し、両者の間で呼び出しをラウンドトリップさせることができます。
+func ServeMCP(ctx context.Context, listener net.Listener, server *mcp.Server) (url string, errs <-chan error, shutdown func() error) {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return server
+	}, nil)
+
+	httpServer := &http.Server{Handler: handler}
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	url = fmt.Sprintf("http://%s/", listener.Addr().String())
+	shutdown = func() error {
+		return httpServer.Shutdown(context.Background())
+	}
+
+	return url, errCh, shutdown
+}
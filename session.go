@@ -0,0 +1,172 @@
+package codex
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Session は単一の `codex mcp` サブプロセスを起動したまま維持し、
+// 複数回の Send / SendStream 呼び出しの間で会話コンテキスト（会話ID、プラン、
+// 作業ディレクトリの状態）を保持します。
+type Session struct {
+	opts    invokeOptions
+	session *mcp.ClientSession
+
+	mu             sync.Mutex
+	conversationID *string
+	subscribers    map[string]eventSender // progressTokenKey -> 送信先
+}
+
+// NewSession は長時間稼働する `codex mcp` サブプロセスを起動し、Session を返します。
+// Invoke を毎回呼び出す場合と異なりサブプロセスの起動コストは初回のみで済み、
+// 以降のターンは同じ会話として継続されます。
+func (codex *Codex) NewSession(ctx context.Context, options ...InvokeOption) (*Session, error) {
+	opts := invokeOptions{}
+	for _, opt := range options {
+		if err := opt(&opts); err != nil {
+			return nil, err
+		}
+	}
+	codex.applyDefaultMCPServers(&opts)
+
+	s := &Session{opts: opts, subscribers: map[string]eventSender{}}
+
+	progressHandler := func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+		event, ok := decodeEvent([]byte(req.Params.Message))
+		if !ok {
+			return
+		}
+		s.dispatch(progressTokenKey(req.Params.GetProgressToken()), event)
+	}
+
+	session, err := codex.connect(ctx, opts, progressHandler)
+	if err != nil {
+		return nil, err
+	}
+	s.session = session
+
+	return s, nil
+}
+
+// dispatch は progress token に対応する subscriber を探し、見つかればイベントを
+// 送信します。subscriber が見つからない場合（すでに SendStream が完了して
+// 登録解除された場合など）は何もしません。
+func (s *Session) dispatch(token string, event Event) {
+	s.mu.Lock()
+	sender, ok := s.subscribers[token]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	sender.send(event)
+}
+
+// buildArguments はこの Session が保持する会話IDを引き継いだ引数を組み立てます。
+func (s *Session) buildArguments(prompt string) map[string]any {
+	arguments := s.opts.buildArguments(prompt)
+
+	s.mu.Lock()
+	conversationID := s.conversationID
+	s.mu.Unlock()
+
+	if conversationID != nil {
+		arguments["conversation-id"] = *conversationID
+	}
+	return arguments
+}
+
+// rememberConversationID は codex からの応答に含まれる会話IDを記録し、
+// 以降のターンで引き継げるようにします。
+func (s *Session) rememberConversationID(meta mcp.Meta) {
+	if meta == nil {
+		return
+	}
+	id, ok := meta["conversation_id"].(string)
+	if !ok || id == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conversationID == nil {
+		s.conversationID = &id
+	}
+}
+
+// Send はプロンプトを送信し、エージェントの最終的な応答テキストを返します。
+// 同じ Session 内での呼び出しは、直前までの会話を引き継いだ追加ターンとして扱われます。
+func (s *Session) Send(ctx context.Context, prompt string) (string, error) {
+	params := &mcp.CallToolParams{
+		Name:      "codex",
+		Arguments: s.buildArguments(prompt),
+	}
+	res, err := s.session.CallTool(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	s.rememberConversationID(res.Meta)
+
+	text := res.Content[0].(*mcp.TextContent).Text
+	if res.IsError {
+		return "", errors.New(text)
+	}
+
+	return text, nil
+}
+
+// SendStream は Send と同様に追加のターンを送信しますが、完了を待たずに
+// エージェントの途中経過を Event チャンネルとして返します。
+// チャンネルは codex の応答が完了すると自動的にクローズされます。
+func (s *Session) SendStream(ctx context.Context, prompt string) (<-chan Event, error) {
+	events := make(chan Event)
+	progressToken := newProgressToken()
+	sender := eventSender{events: events, done: ctx.Done(), sawAgentMessageDelta: &atomic.Bool{}}
+
+	s.mu.Lock()
+	s.subscribers[progressToken] = sender
+	s.mu.Unlock()
+
+	go func() {
+		defer close(events)
+		defer func() {
+			s.mu.Lock()
+			delete(s.subscribers, progressToken)
+			s.mu.Unlock()
+		}()
+
+		params := &mcp.CallToolParams{
+			Name:      "codex",
+			Arguments: s.buildArguments(prompt),
+			Meta:      mcp.Meta{"progressToken": progressToken},
+		}
+		res, err := s.session.CallTool(ctx, params)
+		if err != nil {
+			sender.send(Event{Type: EventError, Err: err})
+			return
+		}
+		s.rememberConversationID(res.Meta)
+
+		text := res.Content[0].(*mcp.TextContent).Text
+		if res.IsError {
+			sender.send(Event{Type: EventError, Err: errors.New(text)})
+			return
+		}
+
+		// codex が既に agent_message_delta 通知でテキストをストリーミング済みなら、
+		// 同じ内容を最終応答として重複送出しない。
+		if !sender.sawAgentMessageDelta.Load() {
+			sender.send(Event{Type: EventAgentMessageDelta, AgentMessageDelta: &AgentMessageDeltaEvent{Delta: text}})
+		}
+	}()
+
+	return events, nil
+}
+
+// Close はセッションが起動した `codex mcp` サブプロセスを終了します。
+func (s *Session) Close() error {
+	return s.session.Close()
+}
@@ -0,0 +1,79 @@
+package codex
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestSessionDispatchStalledConsumerDoesNotBlockOthers は、ある progress token の
+// subscriber がイベントを受信しない（処理が詰まっている）場合でも、別の
+// subscriber への dispatch がブロックされずに完了することを確認します。
+func TestSessionDispatchStalledConsumerDoesNotBlockOthers(t *testing.T) {
+	stalledCtx, cancelStalled := context.WithCancel(context.Background())
+	defer cancelStalled()
+
+	stalled := make(chan Event) // 誰も受信しない
+	active := make(chan Event, 1)
+
+	s := &Session{
+		subscribers: map[string]eventSender{
+			"stalled": {events: stalled, done: stalledCtx.Done()},
+			"active":  {events: active, done: context.Background().Done()},
+		},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		// stalled の consumer は受信しないが、done もまだクローズされていない。
+		// それでも active 宛の dispatch がブロックされてはならない。
+		s.dispatch("active", Event{Type: EventAgentMessageDelta, AgentMessageDelta: &AgentMessageDeltaEvent{Delta: "ok"}})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch to active subscriber blocked on unrelated stalled subscriber")
+	}
+
+	select {
+	case got := <-active:
+		if got.AgentMessageDelta.Delta != "ok" {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+	default:
+		t.Fatal("expected active subscriber to receive the event")
+	}
+}
+
+// TestSessionDispatchUnknownTokenIsNoop は、購読が解除済み（SendStream が
+// 完了した後）の token に対する dispatch が何もせず即座に戻ることを確認します。
+func TestSessionDispatchUnknownTokenIsNoop(t *testing.T) {
+	s := &Session{subscribers: map[string]eventSender{}}
+
+	done := make(chan struct{})
+	go func() {
+		s.dispatch("unknown", Event{Type: EventAgentMessageDelta})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch for unknown token should return immediately")
+	}
+}
+
+// TestSessionRememberConversationIDKeepsFirstValue は、一度会話IDを記録したら
+// 後続の呼び出しで上書きされないことを確認します。
+func TestSessionRememberConversationIDKeepsFirstValue(t *testing.T) {
+	s := &Session{}
+
+	s.rememberConversationID(map[string]any{"conversation_id": "first"})
+	s.rememberConversationID(map[string]any{"conversation_id": "second"})
+
+	if s.conversationID == nil || *s.conversationID != "first" {
+		t.Fatalf("expected conversationID to remain %q, got %v", "first", s.conversationID)
+	}
+}